@@ -0,0 +1,287 @@
+package lru
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+)
+
+// A resident entry: a key/value pair currently held in T1 or T2.
+type arcEntry struct {
+	key  string
+	data interface{}
+}
+
+// ARCCache is an Adaptive Replacement Cache (Megiddo & Modha), which
+// self-tunes between recency (LRU) and frequency (LFU) without a
+// tunable parameter. It maintains four lists: T1 (recently used,
+// resident), T2 (frequently used, resident), and two "ghost" lists, B1
+// and B2, which remember the keys (not the values) of recently evicted
+// T1 and T2 entries. A target size p governs how much of the c-sized
+// cache T1 may occupy; p adapts up when a B1 ghost hit suggests T1 is
+// too small, and down when a B2 ghost hit suggests T2 is too small. The
+// ghost lists cost only a key's worth of memory each, so total overhead
+// stays bounded to roughly 2c entries, and because they remember what
+// was evicted, a one-off scan that floods T1 doesn't evict the
+// genuinely hot T2 working set the way it would under plain LRU.
+type ARCCache struct {
+	// Number of resident entries the cache may hold (|T1|+|T2| <= c).
+	c int64
+
+	// Target size for T1. Ranges over [0, c].
+	p int64
+
+	// Recent, resident. Holds *arcEntry.
+	t1 *list.List
+
+	// Frequent, resident. Holds *arcEntry.
+	t2 *list.List
+
+	// Recent, ghost: evicted T1 keys only, no values. Holds string.
+	b1 *list.List
+
+	// Frequent, ghost: evicted T2 keys only, no values. Holds string.
+	b2 *list.List
+
+	t1Index map[string]*list.Element
+	t2Index map[string]*list.Element
+	b1Index map[string]*list.Element
+	b2Index map[string]*list.Element
+
+	// Callback for eviction. Only fired when a real (non-ghost) entry
+	// is removed.
+	evictedCallback func(key string)
+
+	// Read/Write mutex
+	lock sync.RWMutex
+}
+
+// NewARCCache creates a new ARC cache of the given capacity.
+func NewARCCache(capacity int64, evictedCallback func(key string)) *ARCCache {
+	return &ARCCache{
+		c:               capacity,
+		t1:              list.New(),
+		t2:              list.New(),
+		b1:              list.New(),
+		b2:              list.New(),
+		t1Index:         make(map[string]*list.Element),
+		t2Index:         make(map[string]*list.Element),
+		b1Index:         make(map[string]*list.Element),
+		b2Index:         make(map[string]*list.Element),
+		evictedCallback: evictedCallback,
+	}
+}
+
+// Len returns the number of resident entries in the cache (|T1|+|T2|).
+// Ghost entries don't count: they carry no value.
+func (arc *ARCCache) Len() int {
+	arc.lock.Lock()
+	defer arc.lock.Unlock()
+
+	return arc.t1.Len() + arc.t2.Len()
+}
+
+// Capacity returns the capacity of the cache.
+func (arc *ARCCache) Capacity() int64 {
+	arc.lock.Lock()
+	defer arc.lock.Unlock()
+
+	return arc.c
+}
+
+// HasKey determines whether the given key is resident in the cache
+// (ghost entries don't count) without changing its recency/frequency.
+func (arc *ARCCache) HasKey(key string) bool {
+	arc.lock.Lock()
+	defer arc.lock.Unlock()
+
+	_, ok := arc.t1Index[key]
+	if ok {
+		return true
+	}
+	_, ok = arc.t2Index[key]
+	return ok
+}
+
+// Get an item from the cache. A hit in T1 promotes the entry to the MRU
+// end of T2, since being accessed a second time marks it frequent, not
+// just recent. A hit in T2 simply moves it to T2's MRU end. Returns
+// (item, true) if resident, (nil, false) otherwise.
+func (arc *ARCCache) Get(key string) (interface{}, bool) {
+	arc.lock.Lock()
+	defer arc.lock.Unlock()
+
+	if e, ok := arc.t1Index[key]; ok {
+		entry := e.Value.(*arcEntry)
+		arc.t1.Remove(e)
+		delete(arc.t1Index, key)
+		arc.t2Index[key] = arc.t2.PushFront(entry)
+		return entry.data, true
+	}
+
+	if e, ok := arc.t2Index[key]; ok {
+		arc.t2.MoveToFront(e)
+		return e.Value.(*arcEntry).data, true
+	}
+
+	return nil, false
+}
+
+// Add inserts or updates key in the cache, following the four cases of
+// the ARC algorithm: a resident hit promotes to T2; a ghost hit in B1 or
+// B2 adapts p towards recency or frequency, respectively, before
+// resurrecting the key into T2; and a genuinely new key runs REPLACE (if
+// the cache is full) and is inserted at the MRU end of T1.
+func (arc *ARCCache) Add(key string, data interface{}) {
+	arc.lock.Lock()
+	defer arc.lock.Unlock()
+
+	// Case I: already resident. Promote to T2 and refresh the data.
+	if e, ok := arc.t1Index[key]; ok {
+		arc.t1.Remove(e)
+		delete(arc.t1Index, key)
+		arc.t2Index[key] = arc.t2.PushFront(&arcEntry{key: key, data: data})
+		return
+	}
+	if e, ok := arc.t2Index[key]; ok {
+		e.Value.(*arcEntry).data = data
+		arc.t2.MoveToFront(e)
+		return
+	}
+
+	// Case II: ghost hit in B1. Favor recency: grow p.
+	if e, ok := arc.b1Index[key]; ok {
+		delta := int64(1)
+		if arc.b1.Len() > 0 {
+			if d := int64(arc.b2.Len()) / int64(arc.b1.Len()); d > delta {
+				delta = d
+			}
+		}
+		arc.p = min64(arc.c, arc.p+delta)
+		arc.replace(false)
+
+		arc.b1.Remove(e)
+		delete(arc.b1Index, key)
+		arc.t2Index[key] = arc.t2.PushFront(&arcEntry{key: key, data: data})
+		return
+	}
+
+	// Case III: ghost hit in B2. Favor frequency: shrink p.
+	if e, ok := arc.b2Index[key]; ok {
+		delta := int64(1)
+		if arc.b2.Len() > 0 {
+			if d := int64(arc.b1.Len()) / int64(arc.b2.Len()); d > delta {
+				delta = d
+			}
+		}
+		arc.p = max64(0, arc.p-delta)
+		arc.replace(true)
+
+		arc.b2.Remove(e)
+		delete(arc.b2Index, key)
+		arc.t2Index[key] = arc.t2.PushFront(&arcEntry{key: key, data: data})
+		return
+	}
+
+	// Case IV: a genuinely new key.
+	t1Len, t2Len := int64(arc.t1.Len()), int64(arc.t2.Len())
+	b1Len, b2Len := int64(arc.b1.Len()), int64(arc.b2.Len())
+
+	if t1Len+b1Len == arc.c {
+		if t1Len < arc.c {
+			arc.dropLRU(arc.b1, arc.b1Index)
+			arc.replace(false)
+		} else {
+			// B1 is empty here: T1 alone already fills the cache.
+			arc.evictLRU(arc.t1, arc.t1Index)
+		}
+	} else if t1Len+b1Len < arc.c && t1Len+t2Len+b1Len+b2Len >= arc.c {
+		if t1Len+t2Len+b1Len+b2Len >= 2*arc.c {
+			arc.dropLRU(arc.b2, arc.b2Index)
+		}
+		arc.replace(false)
+	}
+
+	arc.t1Index[key] = arc.t1.PushFront(&arcEntry{key: key, data: data})
+}
+
+// replace evicts the LRU entry of T1 or T2 into the corresponding ghost
+// list, per the ARC REPLACE procedure. inB2 reports whether the key that
+// triggered this call was a B2 ghost hit, which is part of the
+// tie-breaking rule between T1 and T2.
+func (arc *ARCCache) replace(inB2 bool) {
+	t1Len := int64(arc.t1.Len())
+
+	if t1Len > 0 && (t1Len > arc.p || (inB2 && t1Len == arc.p)) {
+		e := arc.t1.Back()
+		entry := e.Value.(*arcEntry)
+		arc.t1.Remove(e)
+		delete(arc.t1Index, entry.key)
+		arc.b1Index[entry.key] = arc.b1.PushFront(entry.key)
+		if arc.evictedCallback != nil {
+			arc.evictedCallback(entry.key)
+		}
+		return
+	}
+
+	if arc.t2.Len() > 0 {
+		e := arc.t2.Back()
+		entry := e.Value.(*arcEntry)
+		arc.t2.Remove(e)
+		delete(arc.t2Index, entry.key)
+		arc.b2Index[entry.key] = arc.b2.PushFront(entry.key)
+		if arc.evictedCallback != nil {
+			arc.evictedCallback(entry.key)
+		}
+	}
+}
+
+// evictLRU removes the LRU resident entry from list l (T1 or T2),
+// firing the eviction callback. Used only when the relevant ghost list
+// is already empty, so there's nowhere to demote the entry to.
+func (arc *ARCCache) evictLRU(l *list.List, index map[string]*list.Element) {
+	e := l.Back()
+	if e == nil {
+		return
+	}
+	entry := e.Value.(*arcEntry)
+	l.Remove(e)
+	delete(index, entry.key)
+	if arc.evictedCallback != nil {
+		arc.evictedCallback(entry.key)
+	}
+}
+
+// dropLRU removes the LRU ghost key from list l (B1 or B2).
+func (arc *ARCCache) dropLRU(l *list.List, index map[string]*list.Element) {
+	e := l.Back()
+	if e == nil {
+		return
+	}
+	l.Remove(e)
+	delete(index, e.Value.(string))
+}
+
+// PrintStats prints information on the cache.
+func (arc *ARCCache) PrintStats() {
+	arc.lock.Lock()
+	defer arc.lock.Unlock()
+
+	fmt.Printf("%d resident (%d in T1, %d in T2), %d ghost (%d in B1, %d in B2), p=%d\n",
+		arc.t1.Len()+arc.t2.Len(), arc.t1.Len(), arc.t2.Len(),
+		arc.b1.Len()+arc.b2.Len(), arc.b1.Len(), arc.b2.Len(), arc.p)
+}
+
+func min64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
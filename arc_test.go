@@ -0,0 +1,106 @@
+package lru_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/khaledmdiab/lru"
+)
+
+func TestARCCache(t *testing.T) {
+	var evicted []string
+	arc := lru.NewARCCache(3, func(key string) {
+		evicted = append(evicted, key)
+	})
+
+	arc.Add("1", "one")
+	arc.Add("2", "two")
+	arc.Add("3", "three")
+
+	if arc.Len() != 3 {
+		t.Error("len != 3")
+	}
+
+	val, ok := arc.Get("1")
+	if !ok || val != "one" {
+		t.Error("1: not in cache or wrong value")
+	}
+	if !arc.HasKey("2") {
+		t.Error("2: not in cache")
+	}
+	if len(evicted) != 0 {
+		t.Error("evicted: not empty")
+	}
+
+	// "1" was promoted to T2 by the Get above. T1 now holds only "2"
+	// and "3"; adding a 4th distinct key should push out T1's LRU ("2"),
+	// not the T2 resident "1".
+	arc.Add("4", "four")
+
+	if len(evicted) != 1 || evicted[0] != "2" {
+		t.Error("evicted: incorrect")
+	}
+	if !arc.HasKey("1") {
+		t.Error("1: should not have been evicted, it is resident in T2")
+	}
+	if arc.HasKey("2") {
+		t.Error("2: should have been evicted")
+	}
+
+	// Re-adding "2" is a ghost (B1) hit: it comes back, and since it
+	// came from B1, p grows, favoring recency for subsequent evictions.
+	arc.Add("2", "TWO")
+	val, ok = arc.Get("2")
+	if !ok || val != "TWO" {
+		t.Error("2: not in cache or wrong value after being re-added")
+	}
+}
+
+func TestARCCacheSurvivesScan(t *testing.T) {
+	var evicted []string
+	arc := lru.NewARCCache(3, func(key string) {
+		evicted = append(evicted, key)
+	})
+
+	// Establish "hot" as a frequent (T2) entry.
+	arc.Add("hot", "H")
+	arc.Get("hot")
+
+	// A long scan of keys touched exactly once each would, under plain
+	// LRU, repeatedly evict whatever is oldest -- including "hot" as
+	// soon as the scan outruns the cache's capacity. Under ARC, "hot"
+	// lives in T2 (frequent), and REPLACE takes T1's LRU entry whenever T1
+	// holds more than the adaptive target p. A one-shot scan has no ghost
+	// hits, so p stays at 0 and every eviction comes from T1.
+	for i := 0; i < 50; i++ {
+		arc.Add(fmt.Sprintf("scan-%d", i), i)
+	}
+
+	if !arc.HasKey("hot") {
+		t.Error("hot: should have survived the scan")
+	}
+	val, ok := arc.Get("hot")
+	if !ok || val != "H" {
+		t.Error("hot: not in cache or wrong value after the scan")
+	}
+	for _, key := range evicted {
+		if key == "hot" {
+			t.Error("hot: should never have been evicted")
+		}
+	}
+
+	// Contrast with plain LRU, which has no notion of frequency and so
+	// cannot protect "hot" from a scan that outlives the cache size.
+	var lruEvicted []string
+	c := lru.NewCache(3, func(key string) {
+		lruEvicted = append(lruEvicted, key)
+	})
+	c.Add("hot", "H")
+	c.Get("hot")
+	for i := 0; i < 50; i++ {
+		c.Add(fmt.Sprintf("scan-%d", i), i)
+	}
+	if c.HasKey("hot") {
+		t.Error("hot: plain LRU is expected to have evicted it by now")
+	}
+}
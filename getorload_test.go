@@ -0,0 +1,97 @@
+package lru_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/khaledmdiab/lru"
+)
+
+func TestGenericCacheGetOrLoad(t *testing.T) {
+	c := lru.NewGenericCache[string, string](10, nil)
+
+	var calls int32
+	release := make(chan struct{})
+	loader := func() (string, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return "loaded", nil
+	}
+
+	const waiters = 5
+	var wg sync.WaitGroup
+	results := make([]string, waiters)
+	errs := make([]error, waiters)
+	for i := 0; i < waiters; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = c.GetOrLoad("key", loader)
+		}(i)
+	}
+
+	close(release)
+	wg.Wait()
+
+	if atomic.LoadInt32(&calls) != 1 {
+		t.Errorf("loader called %d times, want 1", calls)
+	}
+	for i := 0; i < waiters; i++ {
+		if errs[i] != nil || results[i] != "loaded" {
+			t.Errorf("waiter %d: got (%q, %v), want (\"loaded\", nil)", i, results[i], errs[i])
+		}
+	}
+
+	val, ok := c.Get("key")
+	if !ok || val != "loaded" {
+		t.Error("key: not populated in the cache after GetOrLoad")
+	}
+}
+
+func TestGenericCacheGetOrLoadError(t *testing.T) {
+	c := lru.NewGenericCache[string, string](10, nil)
+	wantErr := errors.New("backing store down")
+
+	val, err := c.GetOrLoad("key", func() (string, error) {
+		return "", wantErr
+	})
+	if err != wantErr {
+		t.Errorf("err = %v, want %v", err, wantErr)
+	}
+	if val != "" {
+		t.Errorf("val = %q, want empty", val)
+	}
+	if c.HasKey("key") {
+		t.Error("key: should not be cached after a failed load")
+	}
+}
+
+func TestGenericSegmentCacheGetOrLoad(t *testing.T) {
+	c := lru.NewGenericSegmentCache[string, string](10, nil)
+
+	var calls int32
+	val, err := c.GetOrLoad("segment", func() (string, int64, error) {
+		atomic.AddInt32(&calls, 1)
+		return "bytes", 4, nil
+	})
+	if err != nil || val != "bytes" {
+		t.Errorf("got (%q, %v), want (\"bytes\", nil)", val, err)
+	}
+	if calls != 1 {
+		t.Errorf("loader called %d times, want 1", calls)
+	}
+	if c.UsedCapacity() != 4 {
+		t.Error("used capacity != 4")
+	}
+
+	// A second call for the same key is a cache hit, not another load.
+	val, err = c.GetOrLoad("segment", func() (string, int64, error) {
+		t.Fatal("loader should not be called on a cache hit")
+		return "", 0, nil
+	})
+	if err != nil || val != "bytes" {
+		t.Errorf("got (%q, %v), want (\"bytes\", nil)", val, err)
+	}
+}
@@ -4,57 +4,98 @@ import (
 	"container/list"
 	"fmt"
 	"sync"
+	"time"
 )
 
 // The id cache entry element.
-type cacheEntry struct {
+type cacheEntry[K comparable, V any] struct {
 	// LRU Entry key and value.
-	key string
+	key K
 
 	// The associated data.
-	data interface{}
+	data V
 
 	// If true the entry cannot be evicted.
 	pinned bool
 
 	// Position in the LRU queue. If the entry is pinned this is nil.
 	position *list.Element
+
+	// Generation the entry was stamped with at insertion. An entry is
+	// considered expired once the cache's currentGeneration moves past
+	// it, which is how Purge invalidates the whole cache in O(1).
+	generation int64
+
+	// Wall-clock expiry, or the zero Time if the entry has no TTL.
+	expiresAt time.Time
+}
+
+// CacheOption configures a GenericCache at construction time.
+type CacheOption[K comparable, V any] func(*GenericCache[K, V])
+
+// WithDefaultTTL sets the TTL applied to entries added via Add (as
+// opposed to AddWithTTL, which takes a TTL per call).
+func WithDefaultTTL[K comparable, V any](ttl time.Duration) CacheOption[K, V] {
+	return func(lru *GenericCache[K, V]) {
+		lru.defaultTTL = ttl
+	}
 }
 
-// LRUCache is a least recently used cache implementation with pinned
+// GenericCache is a least recently used cache implementation with pinned
 // members.  Pinned members do not count in the size of the cache when
 // deciding when to evict cache entries.
-type Cache struct {
+type GenericCache[K comparable, V any] struct {
 	// Number of entries in the LRU cache.
 	size int
 
 	// Cache of entries for O(1) lookup.
-	cache map[string]*cacheEntry
+	cache map[K]*cacheEntry[K, V]
 
 	// Queue.
 	q *list.List
 
-	// Callback for eviction.
-	evictedCallback func(key string)
+	// Callback for eviction. Receives the evicted key and value.
+	evictedCallback func(key K, data V)
+
+	// TTL applied to entries added via Add. Zero means no expiry.
+	defaultTTL time.Duration
+
+	// Bumped by Purge to lazily invalidate every entry stamped with an
+	// older generation.
+	currentGeneration int64
+
+	// Non-nil while a janitor goroutine is running; closed by Close to
+	// stop it.
+	janitorStop chan struct{}
+
+	// Lets Close wait for the janitor goroutine to exit.
+	janitorWG sync.WaitGroup
+
+	// In-progress GetOrLoad calls, keyed by the key being loaded, so
+	// concurrent misses for the same key share one loader invocation.
+	inflight map[K]*inflightCall[V]
 
 	// Read/Write mutex
 	lock sync.RWMutex
 }
 
-// NewLRUCache creates a new cache of the given size.
-func NewCache(size int, evictedCallback func(key string)) *Cache {
-	lru := &Cache{
+// NewGenericCache creates a new cache of the given size.
+func NewGenericCache[K comparable, V any](size int, evictedCallback func(key K, data V), opts ...CacheOption[K, V]) *GenericCache[K, V] {
+	lru := &GenericCache[K, V]{
 		size:            size,
-		cache:           make(map[string]*cacheEntry),
+		cache:           make(map[K]*cacheEntry[K, V]),
 		q:               list.New(),
 		evictedCallback: evictedCallback}
+	for _, opt := range opts {
+		opt(lru)
+	}
 	return lru
 
 }
 
 // Len returns the number of items in the cache. This can be greater than the
 // size due to pinned items.
-func (lru *Cache) Len() int {
+func (lru *GenericCache[K, V]) Len() int {
 	lru.lock.Lock()
 	defer lru.lock.Unlock()
 
@@ -62,7 +103,7 @@ func (lru *Cache) Len() int {
 }
 
 // Size returns the size of the cache.
-func (lru *Cache) Size() int {
+func (lru *GenericCache[K, V]) Size() int {
 	lru.lock.Lock()
 	defer lru.lock.Unlock()
 
@@ -70,12 +111,25 @@ func (lru *Cache) Size() int {
 }
 
 // Get an item from the cache. Moves the item to the front of the queue
-// if not pinned. Returns (item, true) if in the cache, (nil, false) otherwise.
-func (lru *Cache) Get(key string) (interface{}, bool) {
+// if not pinned. Returns (item, true) if in the cache, (zero value, false)
+// otherwise. An entry whose TTL has passed, or that was invalidated by a
+// Purge since it was added, is treated as absent and removed lazily.
+func (lru *GenericCache[K, V]) Get(key K) (V, bool) {
 	lru.lock.Lock()
 	defer lru.lock.Unlock()
 
+	return lru.getLocked(key)
+}
+
+// getLocked is Get's implementation. Callers must hold lru.lock.
+func (lru *GenericCache[K, V]) getLocked(key K) (V, bool) {
 	if e, ok := lru.cache[key]; ok {
+		if lru.expired(e, time.Now()) {
+			lru.removeEntry(e)
+			var zero V
+			return zero, false
+		}
+
 		// If the item isn't pinned move to the front of the list.
 		if !e.pinned {
 			lru.q.MoveToFront(e.position)
@@ -83,11 +137,12 @@ func (lru *Cache) Get(key string) (interface{}, bool) {
 
 		return e.data, true
 	}
-	return nil, false
+	var zero V
+	return zero, false
 }
 
 // HasKey determines whether the given key is in the cache without changing LRU order.
-func (lru *Cache) HasKey(key string) bool {
+func (lru *GenericCache[K, V]) HasKey(key K) bool {
 	lru.lock.Lock()
 	defer lru.lock.Unlock()
 
@@ -95,10 +150,34 @@ func (lru *Cache) HasKey(key string) bool {
 	return ok
 }
 
-// Add a new item to the queue, evicting an item from the cache
-// if full.
-func (lru *Cache) Add(key string, data interface{}) {
+// Add a new item to the queue, evicting an item from the cache if full.
+// If a DefaultTTL was configured via WithDefaultTTL, the entry expires
+// after that duration; use AddWithTTL to override it per call.
+func (lru *GenericCache[K, V]) Add(key K, data V) {
+	lru.add(key, data, lru.defaultTTL)
+}
+
+// AddWithTTL is like Add but expires the entry after ttl regardless of
+// any DefaultTTL configured for the cache. A ttl <= 0 means the entry
+// never expires.
+func (lru *GenericCache[K, V]) AddWithTTL(key K, data V, ttl time.Duration) {
+	lru.add(key, data, ttl)
+}
+
+func (lru *GenericCache[K, V]) add(key K, data V, ttl time.Duration) {
 	lru.lock.Lock()
+	lru.addLocked(key, data, ttl)
+	lru.lock.Unlock()
+
+	lru.evict()
+}
+
+// addLocked is add's implementation. Callers must hold lru.lock.
+func (lru *GenericCache[K, V]) addLocked(key K, data V, ttl time.Duration) {
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl)
+	}
 
 	// Check for existing item, replacing the data if already
 	// present.
@@ -107,22 +186,70 @@ func (lru *Cache) Add(key string, data interface{}) {
 			lru.q.MoveToFront(e.position)
 		}
 		e.data = data
-		lru.lock.Unlock()
+		e.generation = lru.currentGeneration
+		e.expiresAt = expiresAt
 		return
 	}
 
-	entry := &cacheEntry{key: key, data: data}
+	entry := &cacheEntry[K, V]{key: key, data: data, generation: lru.currentGeneration, expiresAt: expiresAt}
 	entry.position = lru.q.PushFront(entry)
 
 	lru.cache[key] = entry
+}
+
+// inflightCall tracks a single in-progress GetOrLoad loader invocation so
+// that concurrent misses for the same key share one call.
+type inflightCall[V any] struct {
+	wg   sync.WaitGroup
+	data V
+	err  error
+}
+
+// GetOrLoad returns the cached value for key, or, on a miss, calls loader
+// to produce one. Concurrent GetOrLoad calls for the same missing key
+// coalesce into a single loader invocation; the rest block and receive
+// its result, so an expensive backing store isn't hit once per waiter.
+func (lru *GenericCache[K, V]) GetOrLoad(key K, loader func() (V, error)) (V, error) {
+	lru.lock.Lock()
+	if data, ok := lru.getLocked(key); ok {
+		lru.lock.Unlock()
+		return data, nil
+	}
+
+	if call, ok := lru.inflight[key]; ok {
+		lru.lock.Unlock()
+		call.wg.Wait()
+		return call.data, call.err
+	}
+
+	call := &inflightCall[V]{}
+	call.wg.Add(1)
+	if lru.inflight == nil {
+		lru.inflight = make(map[K]*inflightCall[V])
+	}
+	lru.inflight[key] = call
 	lru.lock.Unlock()
 
-	lru.evict()
+	call.data, call.err = loader()
+	call.wg.Done()
+
+	lru.lock.Lock()
+	delete(lru.inflight, key)
+	if call.err == nil {
+		lru.addLocked(key, call.data, lru.defaultTTL)
+	}
+	lru.lock.Unlock()
+
+	if call.err == nil {
+		lru.evict()
+	}
+
+	return call.data, call.err
 }
 
 // Pin ensures that the item with the given key is not evicted from
 // the cache. Pinned items do not count torwards the cache size.
-func (lru *Cache) Pin(key string) {
+func (lru *GenericCache[K, V]) Pin(key K) {
 	lru.lock.Lock()
 	defer lru.lock.Unlock()
 
@@ -137,7 +264,7 @@ func (lru *Cache) Pin(key string) {
 
 // Unpin removes the cache pin from the item with the given key.
 // The unpinned item is placed at the head of the cache.
-func (lru *Cache) Unpin(key string) {
+func (lru *GenericCache[K, V]) Unpin(key K) {
 	lru.lock.Lock()
 
 	if e, ok := lru.cache[key]; ok {
@@ -152,18 +279,114 @@ func (lru *Cache) Unpin(key string) {
 }
 
 // IsPinned returns true if the key is pinned, false otherwise.
-func (lru *Cache) IsPinned(key string) (bool, error) {
+func (lru *GenericCache[K, V]) IsPinned(key K) (bool, error) {
 	lru.lock.Lock()
 	defer lru.lock.Unlock()
 
 	if e, ok := lru.cache[key]; ok {
 		return e.pinned, nil
 	}
-	return false, fmt.Errorf("%s: not in cache", key)
+	return false, fmt.Errorf("%v: not in cache", key)
+}
+
+// Purge invalidates every entry currently in the cache. Rather than
+// scanning the cache, it bumps the generation counter so that Get and the
+// janitor discover and remove the stale entries lazily.
+func (lru *GenericCache[K, V]) Purge() {
+	lru.lock.Lock()
+	defer lru.lock.Unlock()
+
+	lru.currentGeneration++
+}
+
+// StartJanitor starts a background goroutine that walks the cache once
+// per interval, evicting entries whose TTL has passed or that were
+// invalidated by Purge. This bounds memory growth for entries that are
+// never read again after expiring. Calling StartJanitor while one is
+// already running is a no-op.
+func (lru *GenericCache[K, V]) StartJanitor(interval time.Duration) {
+	lru.lock.Lock()
+	if lru.janitorStop != nil {
+		lru.lock.Unlock()
+		return
+	}
+	stop := make(chan struct{})
+	lru.janitorStop = stop
+	lru.lock.Unlock()
+
+	lru.janitorWG.Add(1)
+	go func() {
+		defer lru.janitorWG.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				lru.sweepExpired()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Close stops the janitor goroutine started by StartJanitor, if any, and
+// waits for it to exit. Close is a no-op if no janitor is running.
+func (lru *GenericCache[K, V]) Close() {
+	lru.lock.Lock()
+	stop := lru.janitorStop
+	lru.janitorStop = nil
+	lru.lock.Unlock()
+
+	if stop != nil {
+		close(stop)
+		lru.janitorWG.Wait()
+	}
+}
+
+// sweepExpired walks the queue from the tail, removing every entry that
+// has expired.
+func (lru *GenericCache[K, V]) sweepExpired() {
+	lru.lock.Lock()
+	defer lru.lock.Unlock()
+
+	now := time.Now()
+	for e := lru.q.Back(); e != nil; {
+		entry := e.Value.(*cacheEntry[K, V])
+		prev := e.Prev()
+		if lru.expired(entry, now) {
+			lru.removeEntry(entry)
+		}
+		e = prev
+	}
+}
+
+// expired reports whether entry is stale: stamped with a generation
+// Purge has since moved past, or its TTL has elapsed. Callers must hold
+// lru.lock.
+func (lru *GenericCache[K, V]) expired(entry *cacheEntry[K, V], now time.Time) bool {
+	if entry.generation < lru.currentGeneration {
+		return true
+	}
+	return !entry.expiresAt.IsZero() && now.After(entry.expiresAt)
+}
+
+// removeEntry removes entry from the cache and queue and fires the
+// eviction callback. Callers must hold lru.lock.
+func (lru *GenericCache[K, V]) removeEntry(entry *cacheEntry[K, V]) {
+	if entry.position != nil {
+		lru.q.Remove(entry.position)
+	}
+	delete(lru.cache, entry.key)
+	if lru.evictedCallback != nil {
+		lru.evictedCallback(entry.key, entry.data)
+	}
 }
 
 // PrintStats prints information on the cache.
-func (lru *Cache) PrintStats() {
+func (lru *GenericCache[K, V]) PrintStats() {
 	lru.lock.Lock()
 	defer lru.lock.Unlock()
 
@@ -171,15 +394,31 @@ func (lru *Cache) PrintStats() {
 }
 
 // Evict the least recently used item from the cache.
-func (lru *Cache) evict() {
+func (lru *GenericCache[K, V]) evict() {
 	lru.lock.Lock()
 	defer lru.lock.Unlock()
 
 	if lru.q.Len() > lru.size {
-		e := lru.q.Remove(lru.q.Back()).(*cacheEntry)
-		delete(lru.cache, e.key)
-		if lru.evictedCallback != nil {
-			lru.evictedCallback(e.key)
-		}
+		e := lru.q.Back().Value.(*cacheEntry[K, V])
+		lru.removeEntry(e)
+	}
+}
+
+// Cache is the string/interface{} instantiation of GenericCache.
+//
+// Deprecated: use GenericCache[K, V] directly for compile-time type
+// safety. Cache is kept for one release as a migration aid and will be
+// removed afterwards.
+type Cache = GenericCache[string, interface{}]
+
+// NewCache creates a new cache of the given size.
+//
+// Deprecated: use NewGenericCache[string, interface{}], or instantiate
+// GenericCache with concrete key/value types instead.
+func NewCache(size int, evictedCallback func(key string)) *Cache {
+	var cb func(key string, data interface{})
+	if evictedCallback != nil {
+		cb = func(key string, _ interface{}) { evictedCallback(key) }
 	}
+	return NewGenericCache[string, interface{}](size, cb)
 }
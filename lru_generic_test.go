@@ -0,0 +1,61 @@
+package lru_test
+
+import (
+	"testing"
+
+	"github.com/khaledmdiab/lru"
+)
+
+func TestGenericCache(t *testing.T) {
+	type evictedEntry struct {
+		key  int
+		data string
+	}
+	var evicted []evictedEntry
+	c := lru.NewGenericCache[int, string](2, func(key int, data string) {
+		evicted = append(evicted, evictedEntry{key, data})
+	})
+
+	c.Add(1, "one")
+	c.Add(2, "two")
+
+	val, ok := c.Get(1)
+	if !ok || val != "one" {
+		t.Error("1: not in cache or wrong value")
+	}
+
+	// LRU is now 1, 2
+	c.Add(3, "three")
+
+	if len(evicted) != 1 || evicted[0] != (evictedEntry{2, "two"}) {
+		t.Error("evicted: incorrect")
+	}
+
+	val, ok = c.Get(4)
+	if ok || val != "" {
+		t.Error("4: should return the zero value on a miss")
+	}
+}
+
+func TestGenericSegmentCache(t *testing.T) {
+	type evictedEntry struct {
+		key  string
+		data int
+	}
+	var evicted []evictedEntry
+	c := lru.NewGenericSegmentCache[string, int](3, func(key string, data int) {
+		evicted = append(evicted, evictedEntry{key, data})
+	})
+
+	c.Add("a", 1, 2)
+	c.Add("b", 2, 2)
+
+	if len(evicted) != 1 || evicted[0] != (evictedEntry{"a", 1}) {
+		t.Error("evicted: incorrect")
+	}
+
+	val, ok := c.Get("b")
+	if !ok || val != 2 {
+		t.Error("b: not in cache or wrong value")
+	}
+}
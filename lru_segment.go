@@ -4,27 +4,47 @@ import (
 	"container/list"
 	"fmt"
 	"sync"
+	"time"
 )
 
 // The id cache entry element. Each element is a video segment
-type cacheSegmentEntry struct {
+type cacheSegmentEntry[K comparable, V any] struct {
 	// LRU Entry key and value.
-	key string
+	key K
 
 	// Segment size
 	size int64
 
 	// The associated data.
-	data interface{}
+	data V
 
 	// Position in the LRU queue. If the entry is pinned this is nil.
 	position *list.Element
+
+	// Generation the entry was stamped with at insertion. An entry is
+	// considered expired once the cache's currentGeneration moves past
+	// it, which is how Purge invalidates the whole cache in O(1).
+	generation int64
+
+	// Wall-clock expiry, or the zero Time if the entry has no TTL.
+	expiresAt time.Time
+}
+
+// SegmentCacheOption configures a GenericSegmentCache at construction time.
+type SegmentCacheOption[K comparable, V any] func(*GenericSegmentCache[K, V])
+
+// WithSegmentDefaultTTL sets the TTL applied to entries added via Add (as
+// opposed to AddWithTTL, which takes a TTL per call).
+func WithSegmentDefaultTTL[K comparable, V any](ttl time.Duration) SegmentCacheOption[K, V] {
+	return func(lru *GenericSegmentCache[K, V]) {
+		lru.defaultTTL = ttl
+	}
 }
 
-// LRUCache is a least recently used cache implementation with pinned
-// members.  Pinned members do not count in the size of the cache when
-// deciding when to evict cache entries.
-type SegmentCache struct {
+// GenericSegmentCache is a least recently used cache implementation whose
+// entries each carry a size. Entries are evicted from the least recently
+// used end until the used capacity fits within the capacity.
+type GenericSegmentCache[K comparable, V any] struct {
 	// Available capacity of LRU cache.
 	capacity int64
 
@@ -32,32 +52,53 @@ type SegmentCache struct {
 	usedCapacity int64
 
 	// SegmentCache of entries for O(1) lookup.
-	cache map[string]*cacheSegmentEntry
+	cache map[K]*cacheSegmentEntry[K, V]
 
 	// Queue.
 	q *list.List
 
-	// Callback for eviction.
-	evictedCallback func(key string)
+	// Callback for eviction. Receives the evicted key and value.
+	evictedCallback func(key K, data V)
+
+	// TTL applied to entries added via Add. Zero means no expiry.
+	defaultTTL time.Duration
+
+	// Bumped by Purge to lazily invalidate every entry stamped with an
+	// older generation.
+	currentGeneration int64
+
+	// Non-nil while a janitor goroutine is running; closed by Close to
+	// stop it.
+	janitorStop chan struct{}
+
+	// Lets Close wait for the janitor goroutine to exit.
+	janitorWG sync.WaitGroup
+
+	// In-progress GetOrLoad calls, keyed by the key being loaded, so
+	// concurrent misses for the same key share one loader invocation.
+	inflight map[K]*inflightSegmentCall[V]
 
 	// Read/Write mutex
 	lock sync.RWMutex
 }
 
-// NewLRUCache creates a new cache of the given size.
-func NewSegmentCache(capacity int64, evictedCallback func(key string)) *SegmentCache {
-	lru := &SegmentCache{
-		capacity:            capacity,
-		usedCapacity:        0,
-		cache:               make(map[string]*cacheSegmentEntry),
-		q:                   list.New(),
-		evictedCallback:     evictedCallback}
+// NewGenericSegmentCache creates a new cache of the given size.
+func NewGenericSegmentCache[K comparable, V any](capacity int64, evictedCallback func(key K, data V), opts ...SegmentCacheOption[K, V]) *GenericSegmentCache[K, V] {
+	lru := &GenericSegmentCache[K, V]{
+		capacity:        capacity,
+		usedCapacity:    0,
+		cache:           make(map[K]*cacheSegmentEntry[K, V]),
+		q:               list.New(),
+		evictedCallback: evictedCallback}
+	for _, opt := range opts {
+		opt(lru)
+	}
 	return lru
 
 }
 
 // Len returns the number of segments in the cache.
-func (lru *SegmentCache) Len() int {
+func (lru *GenericSegmentCache[K, V]) Len() int {
 	lru.lock.Lock()
 	defer lru.lock.Unlock()
 
@@ -65,7 +106,7 @@ func (lru *SegmentCache) Len() int {
 }
 
 // Capacity returns the capacity of the cache.
-func (lru *SegmentCache) Capacity() int64 {
+func (lru *GenericSegmentCache[K, V]) Capacity() int64 {
 	lru.lock.Lock()
 	defer lru.lock.Unlock()
 
@@ -73,29 +114,43 @@ func (lru *SegmentCache) Capacity() int64 {
 }
 
 // UsedCapacity returns the used capacity of the cache.
-func (lru *SegmentCache) UsedCapacity() int64 {
+func (lru *GenericSegmentCache[K, V]) UsedCapacity() int64 {
 	lru.lock.Lock()
 	defer lru.lock.Unlock()
 
 	return lru.usedCapacity
 }
 
-// Get an item from the cache. Moves the item to the front of the queue
-// if not pinned. Returns (item, true) if in the cache, (nil, false) otherwise.
-func (lru *SegmentCache) Get(key string) (interface{}, bool) {
+// Get an item from the cache. Moves the item to the front of the queue.
+// Returns (item, true) if in the cache, (zero value, false)
+// otherwise. An entry whose TTL has passed, or that was invalidated by a
+// Purge since it was added, is treated as absent and removed lazily.
+func (lru *GenericSegmentCache[K, V]) Get(key K) (V, bool) {
 	lru.lock.Lock()
 	defer lru.lock.Unlock()
 
+	return lru.getLocked(key)
+}
+
+// getLocked is Get's implementation. Callers must hold lru.lock.
+func (lru *GenericSegmentCache[K, V]) getLocked(key K) (V, bool) {
 	if e, ok := lru.cache[key]; ok {
+		if lru.expired(e, time.Now()) {
+			lru.removeEntry(e)
+			var zero V
+			return zero, false
+		}
+
 		// Move to the front of the list.
 		lru.q.MoveToFront(e.position)
 		return e.data, true
 	}
-	return nil, false
+	var zero V
+	return zero, false
 }
 
 // HasKey determines whether the given key is in the cache without changing LRU order.
-func (lru *SegmentCache) HasKey(key string) bool {
+func (lru *GenericSegmentCache[K, V]) HasKey(key K) bool {
 	lru.lock.Lock()
 	defer lru.lock.Unlock()
 
@@ -103,29 +158,202 @@ func (lru *SegmentCache) HasKey(key string) bool {
 	return ok
 }
 
-// Add a new item to the queue, evicting an item from the cache
-// if full.
-func (lru *SegmentCache) Add(key string, data interface{}, size int64) {
+// Add a new item to the queue, evicting an item from the cache if full.
+// If a DefaultTTL was configured via WithSegmentDefaultTTL, the entry expires
+// after that duration; use AddWithTTL to override it per call.
+func (lru *GenericSegmentCache[K, V]) Add(key K, data V, size int64) {
+	lru.add(key, data, size, lru.defaultTTL)
+}
+
+// AddWithTTL is like Add but expires the entry after ttl regardless of
+// any DefaultTTL configured for the cache. A ttl <= 0 means the entry
+// never expires.
+func (lru *GenericSegmentCache[K, V]) AddWithTTL(key K, data V, size int64, ttl time.Duration) {
+	lru.add(key, data, size, ttl)
+}
+
+func (lru *GenericSegmentCache[K, V]) add(key K, data V, size int64, ttl time.Duration) {
 	lru.lock.Lock()
+	lru.addLocked(key, data, size, ttl)
+	lru.lock.Unlock()
+
+	lru.evict()
+}
+
+// addLocked is add's implementation. Callers must hold lru.lock.
+func (lru *GenericSegmentCache[K, V]) addLocked(key K, data V, size int64, ttl time.Duration) {
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl)
+	}
+
 	// Check for existing item, replacing the data if already
 	// present.
 	if e, ok := lru.cache[key]; ok {
 		lru.q.MoveToFront(e.position)
+		lru.usedCapacity += size - e.size
 		e.data = data
-		lru.lock.Unlock()
+		e.size = size
+		e.generation = lru.currentGeneration
+		e.expiresAt = expiresAt
 		return
 	}
 
-	entry := &cacheSegmentEntry{key: key, data: data, size: size}
+	entry := &cacheSegmentEntry[K, V]{key: key, data: data, size: size, generation: lru.currentGeneration, expiresAt: expiresAt}
 	entry.position = lru.q.PushFront(entry)
 	lru.usedCapacity += size
 	lru.cache[key] = entry
+}
+
+// inflightSegmentCall tracks a single in-progress GetOrLoad loader
+// invocation so that concurrent misses for the same key share one call.
+type inflightSegmentCall[V any] struct {
+	wg   sync.WaitGroup
+	data V
+	size int64
+	err  error
+}
+
+// GetOrLoad returns the cached value for key, or, on a miss, calls loader
+// to produce one along with its size. Concurrent GetOrLoad calls for the
+// same missing key coalesce into a single loader invocation; the rest
+// block and receive its result, so an expensive backing store (a video
+// segment fetch, say) isn't hit once per waiter.
+func (lru *GenericSegmentCache[K, V]) GetOrLoad(key K, loader func() (V, int64, error)) (V, error) {
+	lru.lock.Lock()
+	if data, ok := lru.getLocked(key); ok {
+		lru.lock.Unlock()
+		return data, nil
+	}
+
+	if call, ok := lru.inflight[key]; ok {
+		lru.lock.Unlock()
+		call.wg.Wait()
+		return call.data, call.err
+	}
+
+	call := &inflightSegmentCall[V]{}
+	call.wg.Add(1)
+	if lru.inflight == nil {
+		lru.inflight = make(map[K]*inflightSegmentCall[V])
+	}
+	lru.inflight[key] = call
 	lru.lock.Unlock()
-	lru.evict()
+
+	call.data, call.size, call.err = loader()
+	call.wg.Done()
+
+	lru.lock.Lock()
+	delete(lru.inflight, key)
+	if call.err == nil {
+		lru.addLocked(key, call.data, call.size, lru.defaultTTL)
+	}
+	lru.lock.Unlock()
+
+	if call.err == nil {
+		lru.evict()
+	}
+
+	return call.data, call.err
+}
+
+// Purge invalidates every entry currently in the cache. Rather than
+// scanning the cache, it bumps the generation counter so that Get and the
+// janitor discover and remove the stale entries lazily.
+func (lru *GenericSegmentCache[K, V]) Purge() {
+	lru.lock.Lock()
+	defer lru.lock.Unlock()
+
+	lru.currentGeneration++
+}
+
+// StartJanitor starts a background goroutine that walks the cache once
+// per interval, evicting entries whose TTL has passed or that were
+// invalidated by Purge. This bounds memory growth for segments that are
+// never read again after expiring. Calling StartJanitor while one is
+// already running is a no-op.
+func (lru *GenericSegmentCache[K, V]) StartJanitor(interval time.Duration) {
+	lru.lock.Lock()
+	if lru.janitorStop != nil {
+		lru.lock.Unlock()
+		return
+	}
+	stop := make(chan struct{})
+	lru.janitorStop = stop
+	lru.lock.Unlock()
+
+	lru.janitorWG.Add(1)
+	go func() {
+		defer lru.janitorWG.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				lru.sweepExpired()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Close stops the janitor goroutine started by StartJanitor, if any, and
+// waits for it to exit. Close is a no-op if no janitor is running.
+func (lru *GenericSegmentCache[K, V]) Close() {
+	lru.lock.Lock()
+	stop := lru.janitorStop
+	lru.janitorStop = nil
+	lru.lock.Unlock()
+
+	if stop != nil {
+		close(stop)
+		lru.janitorWG.Wait()
+	}
+}
+
+// sweepExpired walks the queue from the tail, removing every entry that
+// has expired.
+func (lru *GenericSegmentCache[K, V]) sweepExpired() {
+	lru.lock.Lock()
+	defer lru.lock.Unlock()
+
+	now := time.Now()
+	for e := lru.q.Back(); e != nil; {
+		entry := e.Value.(*cacheSegmentEntry[K, V])
+		prev := e.Prev()
+		if lru.expired(entry, now) {
+			lru.removeEntry(entry)
+		}
+		e = prev
+	}
+}
+
+// expired reports whether entry is stale: stamped with a generation
+// Purge has since moved past, or its TTL has elapsed. Callers must hold
+// lru.lock.
+func (lru *GenericSegmentCache[K, V]) expired(entry *cacheSegmentEntry[K, V], now time.Time) bool {
+	if entry.generation < lru.currentGeneration {
+		return true
+	}
+	return !entry.expiresAt.IsZero() && now.After(entry.expiresAt)
+}
+
+// removeEntry removes entry from the cache and queue, adjusts the used
+// capacity, and fires the eviction callback. Callers must hold lru.lock.
+func (lru *GenericSegmentCache[K, V]) removeEntry(entry *cacheSegmentEntry[K, V]) {
+	lru.q.Remove(entry.position)
+	lru.usedCapacity -= entry.size
+	delete(lru.cache, entry.key)
+	if lru.evictedCallback != nil {
+		lru.evictedCallback(entry.key, entry.data)
+	}
 }
 
 // PrintStats prints information on the cache.
-func (lru *SegmentCache) PrintStats() {
+func (lru *GenericSegmentCache[K, V]) PrintStats() {
 	lru.lock.Lock()
 	defer lru.lock.Unlock()
 
@@ -133,17 +361,33 @@ func (lru *SegmentCache) PrintStats() {
 }
 
 // Evict the least recently used item from the cache.
-func (lru *SegmentCache) evict() {
+func (lru *GenericSegmentCache[K, V]) evict() {
 	lru.lock.Lock()
 	for lru.usedCapacity > lru.capacity {
-		e := lru.q.Remove(lru.q.Back()).(*cacheSegmentEntry)
-		lru.usedCapacity -= e.size
-		delete(lru.cache, e.key)
-		if lru.evictedCallback != nil {
-			lru.evictedCallback(e.key)
-		}
+		e := lru.q.Back().Value.(*cacheSegmentEntry[K, V])
+		lru.removeEntry(e)
 	}
 	lru.lock.Unlock()
 
 	lru.PrintStats()
 }
+
+// SegmentCache is the string/interface{} instantiation of
+// GenericSegmentCache.
+//
+// Deprecated: use GenericSegmentCache[K, V] directly for compile-time
+// type safety. SegmentCache is kept for one release as a migration aid
+// and will be removed afterwards.
+type SegmentCache = GenericSegmentCache[string, interface{}]
+
+// NewSegmentCache creates a new cache of the given size.
+//
+// Deprecated: use NewGenericSegmentCache[string, interface{}], or
+// instantiate GenericSegmentCache with concrete key/value types instead.
+func NewSegmentCache(capacity int64, evictedCallback func(key string)) *SegmentCache {
+	var cb func(key string, data interface{})
+	if evictedCallback != nil {
+		cb = func(key string, _ interface{}) { evictedCallback(key) }
+	}
+	return NewGenericSegmentCache[string, interface{}](capacity, cb)
+}
@@ -0,0 +1,67 @@
+package lru_test
+
+import (
+	"testing"
+
+	"github.com/khaledmdiab/lru"
+)
+
+func TestShardedCache(t *testing.T) {
+	var evicted []string
+	// Capacity is generous relative to the number of keys so that no
+	// single shard can fill up, regardless of how the hash distributes
+	// these particular keys across shards.
+	sc := lru.NewShardedCache(30, 3, func(key string) {
+		evicted = append(evicted, key)
+	})
+
+	if sc.Len() != 0 {
+		t.Error("len != 0")
+	}
+
+	for i := 0; i < 10; i++ {
+		sc.Add(string(rune('a'+i)), i)
+	}
+
+	if sc.Len() != 10 {
+		t.Error("len != 10")
+	}
+
+	for i := 0; i < 10; i++ {
+		key := string(rune('a' + i))
+		val, ok := sc.Get(key)
+		if !ok || val != i {
+			t.Errorf("%s: not in cache or wrong value", key)
+		}
+		if !sc.HasKey(key) {
+			t.Errorf("%s: HasKey false", key)
+		}
+	}
+
+	if len(evicted) != 0 {
+		t.Error("evicted: should be empty, no shard exceeds its capacity")
+	}
+}
+
+func TestShardedSegmentCache(t *testing.T) {
+	var evicted []string
+	sc := lru.NewShardedSegmentCache(10, 4, func(key string) {
+		evicted = append(evicted, key)
+	})
+
+	if sc.Capacity() != 10 {
+		t.Error("capacity != 10: per-shard capacities should add up to the total")
+	}
+
+	sc.Add("a", "A", 3)
+	sc.Add("b", "B", 3)
+
+	if sc.UsedCapacity() != 6 {
+		t.Error("used capacity != 6")
+	}
+
+	val, ok := sc.Get("a")
+	if !ok || val != "A" {
+		t.Error("a: not in cache or wrong value")
+	}
+}
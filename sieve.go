@@ -0,0 +1,167 @@
+package lru
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+)
+
+// The id cache entry element for the SIEVE cache.
+type sieveEntry struct {
+	// SIEVE entry key and value.
+	key string
+
+	// The associated data.
+	data interface{}
+
+	// Set on Get, cleared by the hand as it sweeps past. An entry
+	// survives a sweep if visited is true when the hand reaches it.
+	visited bool
+
+	// Position in the insertion-ordered queue.
+	position *list.Element
+}
+
+// SieveCache is a cache implementation of the SIEVE eviction algorithm
+// (https://cachemon.github.io/SIEVE-website/). Unlike LRU, a Get does not
+// move the entry in the queue; it only sets a visited bit. Eviction is
+// driven by a hand that sweeps from the tail of the queue, clearing
+// visited bits until it finds an entry that is still unvisited, which it
+// evicts. This keeps reads free of list mutation and tends to give
+// a higher hit ratio than LRU under scan-like access patterns.
+type SieveCache struct {
+	// Number of entries in the cache.
+	size int
+
+	// Cache of entries for O(1) lookup.
+	cache map[string]*sieveEntry
+
+	// Queue in insertion order. New entries are pushed to the front.
+	q *list.List
+
+	// The hand used to sweep the queue for eviction. Starts at the
+	// tail and walks towards the head, wrapping around.
+	hand *list.Element
+
+	// Callback for eviction.
+	evictedCallback func(key string)
+
+	// Read/Write mutex
+	lock sync.RWMutex
+}
+
+// NewSieveCache creates a new SIEVE cache of the given size.
+func NewSieveCache(size int, evictedCallback func(key string)) *SieveCache {
+	sieve := &SieveCache{
+		size:            size,
+		cache:           make(map[string]*sieveEntry),
+		q:               list.New(),
+		evictedCallback: evictedCallback}
+	return sieve
+}
+
+// Len returns the number of items in the cache.
+func (sieve *SieveCache) Len() int {
+	sieve.lock.Lock()
+	defer sieve.lock.Unlock()
+
+	return len(sieve.cache)
+}
+
+// Size returns the size of the cache.
+func (sieve *SieveCache) Size() int {
+	sieve.lock.Lock()
+	defer sieve.lock.Unlock()
+
+	return sieve.size
+}
+
+// Get an item from the cache, marking it visited. Returns (item, true) if
+// in the cache, (nil, false) otherwise.
+func (sieve *SieveCache) Get(key string) (interface{}, bool) {
+	sieve.lock.Lock()
+	defer sieve.lock.Unlock()
+
+	if e, ok := sieve.cache[key]; ok {
+		e.visited = true
+		return e.data, true
+	}
+	return nil, false
+}
+
+// HasKey determines whether the given key is in the cache without
+// marking it visited.
+func (sieve *SieveCache) HasKey(key string) bool {
+	sieve.lock.Lock()
+	defer sieve.lock.Unlock()
+
+	_, ok := sieve.cache[key]
+	return ok
+}
+
+// Add a new item to the queue, evicting an item from the cache if full.
+func (sieve *SieveCache) Add(key string, data interface{}) {
+	sieve.lock.Lock()
+
+	// Check for existing item, replacing the data if already present.
+	if e, ok := sieve.cache[key]; ok {
+		e.data = data
+		sieve.lock.Unlock()
+		return
+	}
+
+	entry := &sieveEntry{key: key, data: data}
+	entry.position = sieve.q.PushFront(entry)
+	sieve.cache[key] = entry
+	sieve.lock.Unlock()
+
+	sieve.evict()
+}
+
+// PrintStats prints information on the cache.
+func (sieve *SieveCache) PrintStats() {
+	sieve.lock.Lock()
+	defer sieve.lock.Unlock()
+
+	fmt.Printf("%d records, hand at %v\n", len(sieve.cache), sieve.hand != nil)
+}
+
+// evict runs the hand from its current position (or the tail, if this is
+// the first eviction) until it finds an unvisited entry, clearing visited
+// bits as it passes over visited ones.
+func (sieve *SieveCache) evict() {
+	sieve.lock.Lock()
+	defer sieve.lock.Unlock()
+
+	if sieve.q.Len() <= sieve.size {
+		return
+	}
+
+	e := sieve.hand
+	if e == nil {
+		e = sieve.q.Back()
+	}
+
+	for {
+		entry := e.Value.(*sieveEntry)
+		if !entry.visited {
+			prev := e.Prev()
+			sieve.q.Remove(e)
+			delete(sieve.cache, entry.key)
+			if prev == nil {
+				prev = sieve.q.Back()
+			}
+			sieve.hand = prev
+			if sieve.evictedCallback != nil {
+				sieve.evictedCallback(entry.key)
+			}
+			return
+		}
+
+		entry.visited = false
+		e = e.Prev()
+		if e == nil {
+			e = sieve.q.Back()
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package lru_test
+
+import (
+	"testing"
+
+	"github.com/khaledmdiab/lru"
+)
+
+func TestSieveCache(t *testing.T) {
+	var evicted []string
+	sieve := lru.NewSieveCache(3, func(key string) {
+		evicted = append(evicted, key)
+	})
+	sieve.Add("a", "A")
+	sieve.Add("b", "B")
+	sieve.Add("c", "C")
+	// Queue here is c, b, a (head to tail), none visited.
+
+	if sieve.Len() != 3 {
+		t.Error("len != 3")
+	}
+
+	_, ok := sieve.Get("z")
+	if ok {
+		t.Error("z: in cache")
+	}
+
+	// Visit all three so the hand has nothing to evict on its first pass
+	// but the entry just being added.
+	val, ok := sieve.Get("a")
+	if !ok || val != "A" {
+		t.Error("a: not in cache or wrong value")
+	}
+	sieve.Get("b")
+	sieve.Get("c")
+
+	// Adding "d" forces an eviction: the hand sweeps a, b, c (all
+	// visited), clearing each bit in turn, and finally reaches "d"
+	// itself, which is unvisited, so it is evicted instead of any of
+	// the older entries.
+	sieve.Add("d", "D")
+
+	if len(evicted) != 1 || evicted[0] != "d" {
+		t.Error("evicted: incorrect")
+	}
+	evicted = nil
+
+	if !sieve.HasKey("a") || !sieve.HasKey("b") || !sieve.HasKey("c") {
+		t.Error("a, b, c: not in cache")
+	}
+
+	// "a"'s visited bit was cleared (not evicted) during the previous
+	// sweep. Adding "e" now resumes the hand right where it left off,
+	// at "a", which is still unvisited: this time it gets evicted.
+	sieve.Add("e", "E")
+
+	if len(evicted) != 1 || evicted[0] != "a" {
+		t.Error("evicted: incorrect")
+	}
+	evicted = nil
+
+	if sieve.HasKey("a") {
+		t.Error("a: still in cache")
+	}
+	if !sieve.HasKey("e") {
+		t.Error("e: not in cache")
+	}
+	if sieve.Len() != 3 {
+		t.Error("len != 3")
+	}
+}
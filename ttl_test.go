@@ -0,0 +1,88 @@
+package lru_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/khaledmdiab/lru"
+)
+
+func TestGenericCacheTTL(t *testing.T) {
+	var evicted []string
+	c := lru.NewGenericCache[string, string](10, func(key string, _ string) {
+		evicted = append(evicted, key)
+	})
+
+	c.AddWithTTL("a", "A", 10*time.Millisecond)
+	if val, ok := c.Get("a"); !ok || val != "A" {
+		t.Error("a: not in cache or wrong value")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	if _, ok := c.Get("a"); ok {
+		t.Error("a: should have expired")
+	}
+	if len(evicted) != 1 || evicted[0] != "a" {
+		t.Error("evicted: incorrect")
+	}
+}
+
+func TestGenericCachePurge(t *testing.T) {
+	c := lru.NewGenericCache[string, string](10, nil)
+	c.Add("a", "A")
+	c.Purge()
+
+	if _, ok := c.Get("a"); ok {
+		t.Error("a: should have been purged")
+	}
+
+	c.Add("b", "B")
+	if val, ok := c.Get("b"); !ok || val != "B" {
+		t.Error("b: should survive, added after the purge")
+	}
+}
+
+func TestGenericCacheJanitor(t *testing.T) {
+	var evicted []string
+	c := lru.NewGenericCache[string, string](10, func(key string, _ string) {
+		evicted = append(evicted, key)
+	})
+	defer c.Close()
+
+	c.AddWithTTL("a", "A", 5*time.Millisecond)
+	c.StartJanitor(10 * time.Millisecond)
+
+	time.Sleep(50 * time.Millisecond)
+
+	if c.Len() != 0 {
+		t.Error("len != 0: janitor did not sweep the expired entry")
+	}
+	if len(evicted) != 1 || evicted[0] != "a" {
+		t.Error("evicted: incorrect")
+	}
+}
+
+func TestGenericSegmentCacheTTL(t *testing.T) {
+	var evicted []string
+	c := lru.NewGenericSegmentCache[string, string](10, func(key string, _ string) {
+		evicted = append(evicted, key)
+	})
+
+	c.AddWithTTL("a", "A", 2, 10*time.Millisecond)
+	if c.UsedCapacity() != 2 {
+		t.Error("used capacity != 2")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	if _, ok := c.Get("a"); ok {
+		t.Error("a: should have expired")
+	}
+	if len(evicted) != 1 || evicted[0] != "a" {
+		t.Error("evicted: incorrect")
+	}
+	if c.UsedCapacity() != 0 {
+		t.Error("used capacity != 0: expired segment's size was not reclaimed")
+	}
+}